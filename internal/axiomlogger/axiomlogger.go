@@ -0,0 +1,58 @@
+// Package axiomlogger is a small, dependency-free client for Axiom's
+// HTTP ingest API (https://axiom.co/docs/restapi/ingest). It previously
+// lived in github.com/dash-xd/gospace/internal/axiomlogger, but that path
+// is internal to the gospace module and can't be imported from here, so
+// it's vendored into this module instead of left as a dangling import.
+package axiomlogger
+
+import (
+        "bytes"
+        "encoding/json"
+        "fmt"
+        "net/http"
+)
+
+// Logger posts structured log events to an Axiom dataset's ingest
+// endpoint.
+type Logger struct {
+        endpoint string
+        token    string
+        client   *http.Client
+}
+
+// New returns a Logger that POSTs to Axiom's ingest API for dataset at
+// apiURL (e.g. "https://api.axiom.co"), authenticating with token.
+func New(apiURL, dataset, token string) *Logger {
+        return &Logger{
+                endpoint: fmt.Sprintf("%s/v1/datasets/%s/ingest", apiURL, dataset),
+                token:    token,
+                client:   http.DefaultClient,
+        }
+}
+
+// LogData sends a single event to Axiom. Axiom's ingest endpoint accepts
+// a JSON array of events, so data is wrapped in a one-element array.
+func (l *Logger) LogData(data map[string]interface{}) error {
+        body, err := json.Marshal([]map[string]interface{}{data})
+        if err != nil {
+                return fmt.Errorf("marshaling axiom event: %w", err)
+        }
+
+        req, err := http.NewRequest(http.MethodPost, l.endpoint, bytes.NewReader(body))
+        if err != nil {
+                return fmt.Errorf("building axiom ingest request: %w", err)
+        }
+        req.Header.Set("Content-Type", "application/json")
+        req.Header.Set("Authorization", "Bearer "+l.token)
+
+        resp, err := l.client.Do(req)
+        if err != nil {
+                return fmt.Errorf("sending axiom ingest request: %w", err)
+        }
+        defer resp.Body.Close()
+
+        if resp.StatusCode >= 300 {
+                return fmt.Errorf("axiom ingest returned status %d", resp.StatusCode)
+        }
+        return nil
+}
@@ -0,0 +1,68 @@
+package ringtracer
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestExportChromeTraceIncludesEventsAndLinks(t *testing.T) {
+	tracer := NewTracer("svc")
+
+	linked := tracer.StartSpan("producer", nil)
+	tracer.EndSpan(linked, "OK", nil)
+
+	span := tracer.StartSpanWithLinks("consumer", nil, SpanLink{TraceID: linked.TraceID, SpanID: linked.SpanID})
+	span.AddEvent("cache-miss", map[string]any{"key": "x"})
+	tracer.EndSpan(span, "OK", nil)
+
+	var buf bytes.Buffer
+	if err := tracer.ExportChromeTrace(&buf); err != nil {
+		t.Fatalf("ExportChromeTrace: %v", err)
+	}
+
+	var doc chromeTraceDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("unmarshaling export: %v", err)
+	}
+
+	var sawInstant, sawFlowStart, sawFlowEnd bool
+	for _, ev := range doc.TraceEvents {
+		switch ev.Ph {
+		case "i":
+			if ev.Name != "cache-miss" || ev.S != "t" {
+				t.Fatalf("unexpected instant event: %+v", ev)
+			}
+			sawInstant = true
+		case "s":
+			sawFlowStart = true
+		case "f":
+			sawFlowEnd = true
+		}
+	}
+
+	if !sawInstant {
+		t.Errorf("expected an instant event for the span's AddEvent call, found none")
+	}
+	if !sawFlowStart || !sawFlowEnd {
+		t.Errorf("expected a flow arrow pair for the span link, found start=%v end=%v", sawFlowStart, sawFlowEnd)
+	}
+}
+
+func TestExportChromeTraceSkipsUnfinishedSpans(t *testing.T) {
+	tracer := NewTracer("svc")
+	tracer.StartSpan("still-running", nil)
+
+	var buf bytes.Buffer
+	if err := tracer.ExportChromeTrace(&buf); err != nil {
+		t.Fatalf("ExportChromeTrace: %v", err)
+	}
+
+	var doc chromeTraceDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("unmarshaling export: %v", err)
+	}
+	if len(doc.TraceEvents) != 0 {
+		t.Errorf("got %d trace events for an unfinished span, want 0", len(doc.TraceEvents))
+	}
+}
@@ -0,0 +1,106 @@
+package ringtracer
+
+import "testing"
+
+func TestRingBufferStoreEvictionOrder(t *testing.T) {
+	store := newRingBufferStore("svc", 2)
+
+	a := &Span{TraceID: "t1", SpanID: "a"}
+	b := &Span{TraceID: "t1", SpanID: "b"}
+	c := &Span{TraceID: "t1", SpanID: "c"}
+
+	store.Put(a)
+	store.Put(b)
+	store.Put(c) // should evict a
+
+	got, err := store.Read(ReadOptions{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d spans, want 2", len(got))
+	}
+	if got[0].SpanID != "b" || got[1].SpanID != "c" {
+		t.Fatalf("got spans %v, want [b c]", spanIDs(got))
+	}
+}
+
+func TestRingBufferStoreLimit(t *testing.T) {
+	store := newRingBufferStore("svc", 10)
+	for _, id := range []string{"a", "b", "c", "d"} {
+		store.Put(&Span{TraceID: "t1", SpanID: id})
+	}
+
+	got, err := store.Read(ReadOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d spans, want 2", len(got))
+	}
+	if got[0].SpanID != "c" || got[1].SpanID != "d" {
+		t.Fatalf("got spans %v, want the 2 most recent [c d]", spanIDs(got))
+	}
+}
+
+func TestRingBufferStoreTraceIDFilter(t *testing.T) {
+	store := newRingBufferStore("svc", 10)
+	store.Put(&Span{TraceID: "t1", SpanID: "a"})
+	store.Put(&Span{TraceID: "t2", SpanID: "b"})
+	store.Put(&Span{TraceID: "t1", SpanID: "c"})
+
+	got, err := store.Read(ReadOptions{TraceID: "t1"})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d spans, want 2", len(got))
+	}
+	for _, span := range got {
+		if span.TraceID != "t1" {
+			t.Fatalf("got span from trace %s, want only t1", span.TraceID)
+		}
+	}
+}
+
+func TestRingBufferStoreEvictionDropsFromTraceIDIndex(t *testing.T) {
+	store := newRingBufferStore("svc", 1)
+	store.Put(&Span{TraceID: "t1", SpanID: "a"})
+	store.Put(&Span{TraceID: "t2", SpanID: "b"})
+
+	got, err := store.Read(ReadOptions{TraceID: "t1"})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("got %d spans for evicted trace t1, want 0", len(got))
+	}
+}
+
+func TestNewTracerZeroCapacityFallsBackToDefault(t *testing.T) {
+	tracer := NewTracer("svc", WithCapacity(0))
+	span := tracer.StartSpan("op", nil)
+	tracer.EndSpan(span, "OK", nil) // would panic with an unclamped zero capacity
+
+	if got := tracer.GetTrace(span.TraceID); len(got) != 1 {
+		t.Fatalf("got %d spans, want 1", len(got))
+	}
+}
+
+func TestNewTracerNegativeCapacityFallsBackToDefault(t *testing.T) {
+	tracer := NewTracer("svc", WithCapacity(-5))
+	span := tracer.StartSpan("op", nil)
+	tracer.EndSpan(span, "OK", nil)
+
+	if got := tracer.GetTrace(span.TraceID); len(got) != 1 {
+		t.Fatalf("got %d spans, want 1", len(got))
+	}
+}
+
+func spanIDs(spans []*Span) []string {
+	ids := make([]string, len(spans))
+	for i, s := range spans {
+		ids[i] = s.SpanID
+	}
+	return ids
+}
@@ -0,0 +1,510 @@
+// Package ringtracer is a small, dependency-free Tracer backed by a
+// pluggable SpanStore, whose default implementation is a bounded ring
+// buffer. It is the standalone counterpart to the tracer package, which
+// adds Axiom logging and OTLP/Zipkin export on top of a context-threaded
+// API; ringtracer instead threads spans explicitly and favours a simple
+// query API (SpanStore.Read) over external exporters.
+//
+// Span, SpanEvent, SpanLink and a few other types are fully duplicated
+// between this package and tracer rather than shared, a holdover from
+// the baseline's Tracer.go/tracer.go package-name collision that split
+// one file into two packages. Whether that fork stays permanent or gets
+// unified behind a shared span type is an open follow-up, not something
+// this split settles.
+package ringtracer
+
+import (
+        "crypto/rand"
+        "encoding/hex"
+        "encoding/json"
+        "fmt"
+        "hash/fnv"
+        "io"
+        "log"
+        "strings"
+        "sync"
+        "time"
+)
+
+type Span struct {
+        TraceID      string         `json:"traceId"`
+        SpanID       string         `json:"spanId"`
+        ParentSpanID string         `json:"parentSpanId,omitempty"`
+        Name         string         `json:"name"`
+        StartTime    time.Time      `json:"startTime"`
+        EndTime      *time.Time     `json:"endTime,omitempty"`
+        Attributes   map[string]any `json:"attributes"`
+        Status       string         `json:"status"`
+        // Events records points in time within the span's lifetime, such
+        // as a retry or a cache miss.
+        Events []SpanEvent `json:"events,omitempty"`
+        // Links references spans in other traces, e.g. the producer span
+        // that fed this one in an async fan-in.
+        Links []SpanLink `json:"links,omitempty"`
+}
+
+// SpanEvent is a timestamped annotation attached to a span via AddEvent.
+type SpanEvent struct {
+        Name       string         `json:"name"`
+        Time       time.Time      `json:"time"`
+        Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// SpanLink references a span in another trace, used to join spans that
+// don't have a direct parent/child relationship (fan-in joins, async
+// callbacks).
+type SpanLink struct {
+        TraceID    string         `json:"traceId"`
+        SpanID     string         `json:"spanId"`
+        Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// AddEvent appends a timestamped event to the span.
+func (s *Span) AddEvent(name string, attrs map[string]any) {
+        s.Events = append(s.Events, SpanEvent{
+                Name:       name,
+                Time:       time.Now(),
+                Attributes: attrs,
+        })
+}
+
+// ReadOptions filters a SpanStore query. Zero-valued fields are ignored,
+// so ReadOptions{} returns every span the store holds.
+type ReadOptions struct {
+        TraceID      string
+        Service      string
+        NameContains string
+        MinDuration  time.Duration
+        Status       string
+        Since        time.Time
+        Limit        int
+}
+
+// SpanStore persists finished spans and answers filtered queries over
+// them. Implementations decide their own retention policy; the default is
+// the bounded ringBufferStore returned by NewTracer.
+type SpanStore interface {
+        Put(*Span)
+        Read(ReadOptions) ([]*Span, error)
+}
+
+// ringBufferStore is the default SpanStore: it keeps the capacity most
+// recently finished spans, evicting the oldest on overflow, and indexes
+// them by TraceID so GetTrace stays O(1).
+type ringBufferStore struct {
+        mu          sync.Mutex
+        serviceName string
+        capacity    int
+        spans       []*Span
+        next        int
+
+        byTraceID map[string][]*Span
+}
+
+func newRingBufferStore(serviceName string, capacity int) *ringBufferStore {
+        return &ringBufferStore{
+                serviceName: serviceName,
+                capacity:    capacity,
+                spans:       make([]*Span, 0, capacity),
+                byTraceID:   make(map[string][]*Span),
+        }
+}
+
+func (s *ringBufferStore) Put(span *Span) {
+        s.mu.Lock()
+        defer s.mu.Unlock()
+
+        if len(s.spans) < s.capacity {
+                s.spans = append(s.spans, span)
+        } else {
+                s.evict(s.spans[s.next])
+                s.spans[s.next] = span
+        }
+        s.next = (s.next + 1) % s.capacity
+
+        s.byTraceID[span.TraceID] = append(s.byTraceID[span.TraceID], span)
+}
+
+// evict drops span from the TraceID index when the ring buffer overwrites
+// its slot.
+func (s *ringBufferStore) evict(span *Span) {
+        spans := s.byTraceID[span.TraceID]
+        for i, sp := range spans {
+                if sp == span {
+                        s.byTraceID[span.TraceID] = append(spans[:i], spans[i+1:]...)
+                        break
+                }
+        }
+        if len(s.byTraceID[span.TraceID]) == 0 {
+                delete(s.byTraceID, span.TraceID)
+        }
+}
+
+// ordered returns every span the ring buffer currently holds, oldest
+// first.
+func (s *ringBufferStore) ordered() []*Span {
+        if len(s.spans) < s.capacity {
+                return s.spans
+        }
+        ordered := make([]*Span, 0, len(s.spans))
+        ordered = append(ordered, s.spans[s.next:]...)
+        ordered = append(ordered, s.spans[:s.next]...)
+        return ordered
+}
+
+func (s *ringBufferStore) Read(opts ReadOptions) ([]*Span, error) {
+        s.mu.Lock()
+        defer s.mu.Unlock()
+
+        candidates := s.ordered()
+        if opts.TraceID != "" {
+                candidates = s.byTraceID[opts.TraceID]
+        }
+
+        matches := make([]*Span, 0, len(candidates))
+        for _, span := range candidates {
+                if opts.Service != "" && opts.Service != s.serviceName {
+                        continue
+                }
+                if opts.NameContains != "" && !strings.Contains(span.Name, opts.NameContains) {
+                        continue
+                }
+                if opts.Status != "" && span.Status != opts.Status {
+                        continue
+                }
+                if !opts.Since.IsZero() && span.StartTime.Before(opts.Since) {
+                        continue
+                }
+                if opts.MinDuration > 0 && (span.EndTime == nil || span.EndTime.Sub(span.StartTime) < opts.MinDuration) {
+                        continue
+                }
+                matches = append(matches, span)
+        }
+
+        if opts.Limit > 0 && len(matches) > opts.Limit {
+                matches = matches[len(matches)-opts.Limit:]
+        }
+
+        return matches, nil
+}
+
+// defaultCapacity is the ring buffer size used when NewTracer is called
+// without WithCapacity.
+const defaultCapacity = 1024
+
+// TracerOption configures a Tracer constructed by NewTracer.
+type TracerOption func(*tracerConfig)
+
+type tracerConfig struct {
+        capacity int
+}
+
+// WithCapacity sets how many finished spans the Tracer's default
+// SpanStore retains before evicting the oldest. Non-positive values fall
+// back to defaultCapacity rather than leaving the ring buffer unable to
+// hold a single span.
+func WithCapacity(n int) TracerOption {
+        return func(c *tracerConfig) {
+                if n <= 0 {
+                        n = defaultCapacity
+                }
+                c.capacity = n
+        }
+}
+
+type Tracer struct {
+        ServiceName string
+        Store       SpanStore
+
+        mu        sync.Mutex
+        openSpans map[string]*Span
+}
+
+func NewTracer(serviceName string, opts ...TracerOption) *Tracer {
+        cfg := tracerConfig{capacity: defaultCapacity}
+        for _, opt := range opts {
+                opt(&cfg)
+        }
+
+        return &Tracer{
+                ServiceName: serviceName,
+                Store:       newRingBufferStore(serviceName, cfg.capacity),
+                openSpans:   make(map[string]*Span),
+        }
+}
+
+func (t *Tracer) generateSecureID() string {
+        b := make([]byte, 16)
+        _, err := rand.Read(b)
+        if err != nil {
+                log.Fatalf("Error generating random bytes: %v", err)
+        }
+        return hex.EncodeToString(b)
+}
+
+func (t *Tracer) StartSpan(name string, parentSpanID *string) *Span {
+        return t.startSpan(name, parentSpanID, nil)
+}
+
+// StartSpanWithLinks behaves like StartSpan but attaches links, letting a
+// span reference spans in other traces (fan-in joins, async callbacks).
+func (t *Tracer) StartSpanWithLinks(name string, parentSpanID *string, links ...SpanLink) *Span {
+        return t.startSpan(name, parentSpanID, links)
+}
+
+func (t *Tracer) startSpan(name string, parentSpanID *string, links []SpanLink) *Span {
+        var traceID string
+        if parentSpanID != nil {
+                t.mu.Lock()
+                if parent, ok := t.openSpans[*parentSpanID]; ok {
+                        traceID = parent.TraceID
+                }
+                t.mu.Unlock()
+        }
+        if traceID == "" {
+                traceID = t.generateSecureID()
+        }
+
+        spanID := t.generateSecureID()
+        startTime := time.Now()
+
+        span := &Span{
+                TraceID:      traceID,
+                SpanID:       spanID,
+                ParentSpanID: "",
+                Name:         name,
+                StartTime:    startTime,
+                Attributes:   make(map[string]any),
+                Status:       "UNSET",
+                Links:        links,
+        }
+
+        if parentSpanID != nil {
+                span.ParentSpanID = *parentSpanID
+        }
+
+        t.mu.Lock()
+        t.openSpans[span.SpanID] = span
+        t.mu.Unlock()
+
+        return span
+}
+
+func (t *Tracer) EndSpan(span *Span, status string, attributes map[string]any) {
+        endTime := time.Now()
+        span.EndTime = &endTime
+        span.Status = status
+        for k, v := range attributes {
+                span.Attributes[k] = v
+        }
+
+        t.mu.Lock()
+        delete(t.openSpans, span.SpanID)
+        t.mu.Unlock()
+
+        t.Store.Put(span)
+}
+
+func (t *Tracer) GetTrace(traceID string) []*Span {
+        spans, err := t.Store.Read(ReadOptions{TraceID: traceID})
+        if err != nil {
+                log.Printf("Error reading trace %s: %v", traceID, err)
+                return nil
+        }
+        return spans
+}
+
+func (t *Tracer) ExportTraces() {
+        fmt.Println("Exporting traces...")
+
+        spans, err := t.Store.Read(ReadOptions{})
+        if err != nil {
+                log.Fatalf("Error reading traces: %v", err)
+        }
+
+        byTraceID := make(map[string][]*Span)
+        for _, span := range spans {
+                byTraceID[span.TraceID] = append(byTraceID[span.TraceID], span)
+        }
+
+        jsonData, err := json.MarshalIndent(byTraceID, "", "  ")
+        if err != nil {
+                log.Fatalf("Error marshaling traces: %v", err)
+        }
+        fmt.Println(string(jsonData))
+}
+
+// chromeTraceEvent is a single entry in the Chrome Trace Event Format
+// (https://chromium.googlesource.com/catapult/+/refs/heads/main/tracing/README.md),
+// the JSON schema understood by chrome://tracing and Perfetto.
+type chromeTraceEvent struct {
+        Name string         `json:"name"`
+        Cat  string         `json:"cat"`
+        Ph   string         `json:"ph"`
+        Ts   int64          `json:"ts"`
+        Pid  int            `json:"pid"`
+        Tid  int            `json:"tid"`
+        ID   string         `json:"id,omitempty"`
+        Bp   string         `json:"bp,omitempty"`
+        S    string         `json:"s,omitempty"`
+        Args map[string]any `json:"args,omitempty"`
+}
+
+type chromeTraceDocument struct {
+        TraceEvents     []chromeTraceEvent `json:"traceEvents"`
+        DisplayTimeUnit string             `json:"displayTimeUnit"`
+}
+
+// ExportChromeTrace serialises every completed span held by the tracer's
+// store into the Chrome Trace Event JSON format, ready to be opened in
+// chrome://tracing or Perfetto without running a collector. Span events
+// become instant ("i") events and span links become flow arrows, the
+// same as the parent/child relationship.
+func (t *Tracer) ExportChromeTrace(w io.Writer) error {
+        spans, err := t.Store.Read(ReadOptions{})
+        if err != nil {
+                return fmt.Errorf("reading spans: %w", err)
+        }
+
+        pid := hashToInt(t.ServiceName)
+        tids := make(map[string]int)
+        nextTid := 1
+        tidFor := func(traceID string) int {
+                if tid, ok := tids[traceID]; ok {
+                        return tid
+                }
+                tid := nextTid
+                tids[traceID] = tid
+                nextTid++
+                return tid
+        }
+        spansByID := make(map[string]*Span, len(spans))
+        for _, span := range spans {
+                spansByID[span.SpanID] = span
+        }
+
+        doc := chromeTraceDocument{DisplayTimeUnit: "ns"}
+
+        for _, span := range spans {
+                if span.EndTime == nil {
+                        continue
+                }
+
+                tid := tidFor(span.TraceID)
+
+                args := make(map[string]any, len(span.Attributes))
+                for k, v := range span.Attributes {
+                        args[k] = v
+                }
+
+                doc.TraceEvents = append(doc.TraceEvents,
+                        chromeTraceEvent{
+                                Name: span.Name,
+                                Cat:  t.ServiceName,
+                                Ph:   "B",
+                                Ts:   span.StartTime.UnixMicro(),
+                                Pid:  pid,
+                                Tid:  tid,
+                                Args: args,
+                        },
+                        chromeTraceEvent{
+                                Name: span.Name,
+                                Cat:  t.ServiceName,
+                                Ph:   "E",
+                                Ts:   span.EndTime.UnixMicro(),
+                                Pid:  pid,
+                                Tid:  tid,
+                        },
+                )
+
+                for _, ev := range span.Events {
+                        evArgs := make(map[string]any, len(ev.Attributes))
+                        for k, v := range ev.Attributes {
+                                evArgs[k] = v
+                        }
+                        doc.TraceEvents = append(doc.TraceEvents, chromeTraceEvent{
+                                Name: ev.Name,
+                                Cat:  t.ServiceName,
+                                Ph:   "i",
+                                S:    "t",
+                                Ts:   ev.Time.UnixMicro(),
+                                Pid:  pid,
+                                Tid:  tid,
+                                Args: evArgs,
+                        })
+                }
+
+                for _, link := range span.Links {
+                        linked, ok := spansByID[link.SpanID]
+                        if !ok {
+                                continue
+                        }
+
+                        flowID := "link-" + link.SpanID + "-" + span.SpanID
+                        doc.TraceEvents = append(doc.TraceEvents,
+                                chromeTraceEvent{
+                                        Name: span.Name,
+                                        Cat:  t.ServiceName,
+                                        Ph:   "s",
+                                        Ts:   linked.StartTime.UnixMicro(),
+                                        Pid:  pid,
+                                        Tid:  tidFor(linked.TraceID),
+                                        ID:   flowID,
+                                },
+                                chromeTraceEvent{
+                                        Name: span.Name,
+                                        Cat:  t.ServiceName,
+                                        Ph:   "f",
+                                        Bp:   "e",
+                                        Ts:   span.StartTime.UnixMicro(),
+                                        Pid:  pid,
+                                        Tid:  tid,
+                                        ID:   flowID,
+                                },
+                        )
+                }
+
+                if span.ParentSpanID == "" {
+                        continue
+                }
+
+                parent, ok := spansByID[span.ParentSpanID]
+                if !ok {
+                        continue
+                }
+
+                flowID := "flow-" + span.SpanID
+                doc.TraceEvents = append(doc.TraceEvents,
+                        chromeTraceEvent{
+                                Name: span.Name,
+                                Cat:  t.ServiceName,
+                                Ph:   "s",
+                                Ts:   parent.StartTime.UnixMicro(),
+                                Pid:  pid,
+                                Tid:  tidFor(parent.TraceID),
+                                ID:   flowID,
+                        },
+                        chromeTraceEvent{
+                                Name: span.Name,
+                                Cat:  t.ServiceName,
+                                Ph:   "f",
+                                Bp:   "e",
+                                Ts:   span.StartTime.UnixMicro(),
+                                Pid:  pid,
+                                Tid:  tid,
+                                ID:   flowID,
+                        },
+                )
+        }
+
+        enc := json.NewEncoder(w)
+        return enc.Encode(doc)
+}
+
+// hashToInt derives a stable, process-independent integer identifier from
+// a string, used to give each service a consistent Chrome Trace pid.
+func hashToInt(s string) int {
+        h := fnv.New32a()
+        h.Write([]byte(s))
+        return int(h.Sum32())
+}
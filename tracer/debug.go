@@ -0,0 +1,209 @@
+package tracer
+
+import (
+        "html/template"
+        "net/http"
+        "sort"
+        "strings"
+        "time"
+)
+
+// spanNode is one node of a rendered span tree: a span plus its offset
+// from the trace's root start time and its children, nested the same way
+// ParentSpanID links them.
+type spanNode struct {
+        Span     *Span
+        Offset   time.Duration
+        Duration time.Duration
+        Children []*spanNode
+}
+
+// buildSpanTrees groups spans by ParentSpanID and returns one spanNode per
+// root (a span with no ParentSpanID), offsetting every descendant relative
+// to the earliest root's StartTime.
+func buildSpanTrees(spans []*Span) []*spanNode {
+        byParent := make(map[string][]*Span)
+        var roots []*Span
+        for _, span := range spans {
+                if span.ParentSpanID == "" {
+                        roots = append(roots, span)
+                } else {
+                        byParent[span.ParentSpanID] = append(byParent[span.ParentSpanID], span)
+                }
+        }
+        if len(roots) == 0 {
+                return nil
+        }
+
+        origin := roots[0].StartTime
+        for _, root := range roots {
+                if root.StartTime.Before(origin) {
+                        origin = root.StartTime
+                }
+        }
+
+        var build func(*Span) *spanNode
+        build = func(span *Span) *spanNode {
+                node := &spanNode{Span: span, Offset: span.StartTime.Sub(origin)}
+                if span.EndTime != nil {
+                        node.Duration = span.EndTime.Sub(span.StartTime)
+                }
+                for _, child := range byParent[span.SpanID] {
+                        node.Children = append(node.Children, build(child))
+                }
+                return node
+        }
+
+        nodes := make([]*spanNode, 0, len(roots))
+        for _, root := range roots {
+                nodes = append(nodes, build(root))
+        }
+        return nodes
+}
+
+// DebugHandler returns an http.Handler that mounts /trace/, /trace/{name}
+// and /trace/{traceID}, rendering an HTML page of the most recent and
+// longest invocation of each span name (or the full tree of a single
+// trace), each drawn as a nested tree of child spans. This mirrors the
+// lightweight "last / longest" trace inspection pattern used in gopls'
+// debug server, giving users a zero-dependency way to diagnose latency
+// outliers without exporting to an external backend.
+func (t *Tracer) DebugHandler() http.Handler {
+        mux := http.NewServeMux()
+        mux.HandleFunc("/trace/", t.handleDebugTrace)
+        return mux
+}
+
+// handleDebugTrace copies out whatever spans it needs while holding
+// t.mu, then renders with the lock released, so a slow or hanging
+// client reading the response body doesn't block every EndSpan in the
+// process.
+func (t *Tracer) handleDebugTrace(w http.ResponseWriter, r *http.Request) {
+        segment := strings.TrimPrefix(r.URL.Path, "/trace/")
+
+        if segment == "" {
+                t.renderIndex(w)
+                return
+        }
+
+        t.mu.Lock()
+        last, hasLast := t.last[segment]
+        longest := t.longest[segment]
+        var lastSpans, longestSpans []*Span
+        if hasLast {
+                lastSpans = append([]*Span(nil), t.traces[last.TraceID]...)
+                if longest != nil {
+                        longestSpans = append([]*Span(nil), t.traces[longest.TraceID]...)
+                }
+        }
+        traceSpans, hasTrace := t.traces[segment]
+        if hasTrace {
+                traceSpans = append([]*Span(nil), traceSpans...)
+        }
+        t.mu.Unlock()
+
+        if hasLast {
+                t.renderName(w, segment, lastSpans, longestSpans)
+                return
+        }
+
+        if hasTrace {
+                t.renderTrace(w, segment, traceSpans)
+                return
+        }
+
+        http.NotFound(w, r)
+}
+
+func (t *Tracer) renderIndex(w http.ResponseWriter) {
+        t.mu.Lock()
+        names := make([]string, 0, len(t.last))
+        for name := range t.last {
+                names = append(names, name)
+        }
+        t.mu.Unlock()
+        sort.Strings(names)
+
+        if err := debugIndexTmpl.Execute(w, names); err != nil {
+                http.Error(w, err.Error(), http.StatusInternalServerError)
+        }
+}
+
+func (t *Tracer) renderName(w http.ResponseWriter, name string, lastSpans, longestSpans []*Span) {
+        data := struct {
+                Name    string
+                Last    []*spanNode
+                Longest []*spanNode
+        }{
+                Name: name,
+                Last: buildSpanTrees(lastSpans),
+        }
+        if longestSpans != nil {
+                data.Longest = buildSpanTrees(longestSpans)
+        }
+
+        if err := debugNameTmpl.Execute(w, data); err != nil {
+                http.Error(w, err.Error(), http.StatusInternalServerError)
+        }
+}
+
+func (t *Tracer) renderTrace(w http.ResponseWriter, traceID string, spans []*Span) {
+        data := struct {
+                TraceID string
+                Trees   []*spanNode
+        }{
+                TraceID: traceID,
+                Trees:   buildSpanTrees(spans),
+        }
+
+        if err := debugTraceTmpl.Execute(w, data); err != nil {
+                http.Error(w, err.Error(), http.StatusInternalServerError)
+        }
+}
+
+var debugFuncs = template.FuncMap{
+        "round": func(d time.Duration) time.Duration { return d.Round(time.Microsecond) },
+}
+
+const spanNodeTmplSource = `
+{{define "spanNode"}}
+<li>
+  <span class="span-name">{{.Span.Name}}</span>
+  <span class="span-meta">offset={{round .Offset}} duration={{round .Duration}} status={{.Span.Status}}</span>
+  {{if .Span.Attributes}}
+  <ul class="span-attrs">
+    {{range $k, $v := .Span.Attributes}}<li>{{$k}}={{$v}}</li>{{end}}
+  </ul>
+  {{end}}
+  {{if .Children}}
+  <ul>{{range .Children}}{{template "spanNode" .}}{{end}}</ul>
+  {{end}}
+</li>
+{{end}}
+`
+
+var debugIndexTmpl = template.Must(template.New("index").Funcs(debugFuncs).Parse(spanNodeTmplSource + `
+<html><head><title>tracer debug</title></head><body>
+<h1>Span names</h1>
+<ul>
+{{range .}}<li><a href="/trace/{{.}}">{{.}}</a></li>{{end}}
+</ul>
+</body></html>
+`))
+
+var debugNameTmpl = template.Must(template.New("name").Funcs(debugFuncs).Parse(spanNodeTmplSource + `
+<html><head><title>{{.Name}} - tracer debug</title></head><body>
+<h1>{{.Name}}</h1>
+<h2>Most recent</h2>
+<ul>{{range .Last}}{{template "spanNode" .}}{{end}}</ul>
+<h2>Longest</h2>
+<ul>{{range .Longest}}{{template "spanNode" .}}{{end}}</ul>
+</body></html>
+`))
+
+var debugTraceTmpl = template.Must(template.New("trace").Funcs(debugFuncs).Parse(spanNodeTmplSource + `
+<html><head><title>{{.TraceID}} - tracer debug</title></head><body>
+<h1>Trace {{.TraceID}}</h1>
+<ul>{{range .Trees}}{{template "spanNode" .}}{{end}}</ul>
+</body></html>
+`))
@@ -0,0 +1,789 @@
+// Package tracer is a small, dependency-free Tracer with W3C Trace Context
+// propagation, OTLP/HTTP and Zipkin v2 JSON export (and ingest), Axiom
+// logging, and a debug HTTP endpoint for recent/longest traces. It is the
+// counterpart to the ringtracer package, which threads spans explicitly
+// through a bounded ring buffer instead of a context and favours a query
+// API over external exporters; see ringtracer's package doc for the
+// duplicated-span-types follow-up shared between the two.
+package tracer
+
+import (
+        "bytes"
+        "context"
+        "crypto/rand"
+        "encoding/hex"
+        "encoding/json"
+        "fmt"
+        "io"
+        "log"
+        "net/http"
+        "strconv"
+        "strings"
+        "sync"
+        "time"
+
+        "github.com/dash-xd/tracer/internal/axiomlogger"
+)
+
+type Span struct {
+        TraceID      string `json:"traceId"`
+        SpanID       string `json:"spanId"`
+        ParentSpanID string `json:"parentSpanId,omitempty"`
+        Name         string `json:"name"`
+        // Kind classifies the span's relationship to a remote call, using
+        // Zipkin's vocabulary: CLIENT, SERVER, PRODUCER or CONSUMER. Left
+        // empty for spans with no such relationship.
+        Kind       string         `json:"kind,omitempty"`
+        StartTime  time.Time      `json:"startTime"`
+        EndTime    *time.Time     `json:"endTime,omitempty"`
+        Attributes map[string]any `json:"attributes"`
+        Status     string         `json:"status"`
+        // Events records points in time within the span's lifetime, such
+        // as a retry or a cache miss.
+        Events []SpanEvent `json:"events,omitempty"`
+        // Links references spans in other traces, e.g. the producer span
+        // that fed this one in an async fan-in.
+        Links []SpanLink `json:"links,omitempty"`
+}
+
+// SpanEvent is a timestamped annotation attached to a span via AddEvent.
+type SpanEvent struct {
+        Name       string         `json:"name"`
+        Time       time.Time      `json:"time"`
+        Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// SpanLink references a span in another trace, used to join spans that
+// don't have a direct parent/child relationship (fan-in joins, async
+// callbacks).
+type SpanLink struct {
+        TraceID    string         `json:"traceId"`
+        SpanID     string         `json:"spanId"`
+        Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// AddEvent appends a timestamped event to the span.
+func (s *Span) AddEvent(name string, attrs map[string]any) {
+        s.Events = append(s.Events, SpanEvent{
+                Name:       name,
+                Time:       time.Now(),
+                Attributes: attrs,
+        })
+}
+
+type Tracer struct {
+        ServiceName string
+        Logger      *axiomlogger.Logger
+
+        mu sync.Mutex
+
+        // pendingOTLP accumulates finished spans between calls to
+        // ExportOTLP, which drains and clears it on each successful export.
+        pendingOTLP []*Span
+
+        // pendingZipkin accumulates finished spans between calls to
+        // ExportZipkin, which drains and clears it on each successful
+        // export. Spans ingested via IngestZipkin are appended here too, so
+        // they flow back out through ExportZipkin, but deliberately not
+        // into pendingOTLP: an ingested span came from some other
+        // upstream's Zipkin endpoint, not this tracer's own instrumentation,
+        // and forwarding it to OTLP would relabel it under this tracer's
+        // ServiceName.
+        pendingZipkin []*Span
+
+        // traces holds every finished span, grouped by TraceID, so
+        // DebugHandler can render a trace as a tree. traceOrder tracks the
+        // TraceIDs in traces in first-seen order, so recordFinishedSpan can
+        // evict the oldest once defaultMaxTraces is exceeded. traceRefs
+        // counts how many of the last/longest entries point into a given
+        // trace, so that trace isn't evicted out from under them.
+        traces     map[string][]*Span
+        traceOrder []string
+        traceRefs  map[string]int
+
+        // last and longest index finished spans by Name: last is
+        // overwritten on every EndSpan, longest only when a span's
+        // duration exceeds the one already stored. Both back
+        // DebugHandler's per-name "most recent" / "longest" views, and are
+        // bounded the same way traces is, via nameOrder.
+        last      map[string]*Span
+        longest   map[string]*Span
+        nameOrder []string
+}
+
+// defaultMaxTraces bounds how many distinct traces (and span names) the
+// debug-serving maps retain, evicting the oldest once exceeded, the same
+// way the ringtracer package's ring buffer caps its spans.
+const defaultMaxTraces = 1024
+
+func NewTracer(serviceName string, logger *axiomlogger.Logger) *Tracer {
+        return &Tracer{
+                ServiceName: serviceName,
+                Logger:      logger,
+                traces:      make(map[string][]*Span),
+                traceRefs:   make(map[string]int),
+                last:        make(map[string]*Span),
+                longest:     make(map[string]*Span),
+        }
+}
+
+// generateSecureID returns a random hex-encoded ID of n bytes, regenerating
+// on the vanishingly rare all-zero result since the W3C Trace Context spec
+// treats an all-zero trace or span ID as invalid.
+func (t *Tracer) generateSecureID(n int) string {
+        b := make([]byte, n)
+        for {
+                _, err := rand.Read(b)
+                if err != nil {
+                        log.Fatalf("Error generating random bytes: %v", err)
+                }
+                if !isAllZero(b) {
+                        return hex.EncodeToString(b)
+                }
+        }
+}
+
+func isAllZero(b []byte) bool {
+        for _, v := range b {
+                if v != 0 {
+                        return false
+                }
+        }
+        return true
+}
+
+// generateTraceID returns a 16-byte trace ID, as required by W3C Trace
+// Context.
+func (t *Tracer) generateTraceID() string {
+        return t.generateSecureID(16)
+}
+
+// generateSpanID returns an 8-byte span ID, as required by W3C Trace
+// Context.
+func (t *Tracer) generateSpanID() string {
+        return t.generateSecureID(8)
+}
+
+func (t *Tracer) StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+        return t.startSpan(ctx, name, nil)
+}
+
+// StartSpanWithLinks behaves like StartSpan but attaches links, letting a
+// span reference spans in other traces (fan-in joins, async callbacks).
+func (t *Tracer) StartSpanWithLinks(ctx context.Context, name string, links ...SpanLink) (context.Context, *Span) {
+        return t.startSpan(ctx, name, links)
+}
+
+func (t *Tracer) startSpan(ctx context.Context, name string, links []SpanLink) (context.Context, *Span) {
+        var parentSpanID *string
+        if parentSpan, ok := ctx.Value("span").(*Span); ok {
+                parentSpanIDPtr := parentSpan.SpanID
+                parentSpanID = &parentSpanIDPtr
+        }
+
+        traceID := ""
+        if parentSpanID != nil {
+                traceID = ctx.Value("traceID").(string)
+        } else {
+                traceID = t.generateTraceID()
+        }
+
+        spanID := t.generateSpanID()
+        startTime := time.Now()
+
+        span := &Span{
+                TraceID:      traceID,
+                SpanID:       spanID,
+                ParentSpanID: "",
+                Name:         name,
+                StartTime:    startTime,
+                Attributes:   make(map[string]any),
+                Status:       "UNSET",
+                Links:        links,
+        }
+
+        if parentSpanID != nil {
+                span.ParentSpanID = *parentSpanID
+        }
+
+        newCtx := context.WithValue(ctx, "span", span)
+        newCtx = context.WithValue(newCtx, "traceID", traceID)
+
+        return newCtx, span
+}
+
+func (t *Tracer) EndSpan(ctx context.Context, span *Span, status string, attributes map[string]any) {
+        endTime := time.Now()
+        span.EndTime = &endTime
+        span.Status = status
+        for k, v := range attributes {
+                span.Attributes[k] = v
+        }
+
+        data := map[string]interface{}{
+                "traceId":      span.TraceID,
+                "spanId":       span.SpanID,
+                "parentSpanId": span.ParentSpanID,
+                "name":         span.Name,
+                "startTime":    span.StartTime,
+                "endTime":      span.EndTime,
+                "attributes":   span.Attributes,
+                "status":       span.Status,
+                "events":       span.Events,
+                "links":        span.Links,
+        }
+        if err := t.Logger.LogData(data); err != nil {
+                log.Printf("Error logging to Axiom: %v", err)
+        }
+
+        t.recordFinishedSpan(span)
+}
+
+// recordFinishedSpan indexes span into every structure a locally-started
+// span feeds: both the OTLP and Zipkin export buffers, and the debug
+// "last" / "longest" / per-trace views.
+func (t *Tracer) recordFinishedSpan(span *Span) {
+        t.mu.Lock()
+        defer t.mu.Unlock()
+
+        t.pendingOTLP = append(t.pendingOTLP, span)
+        t.pendingZipkin = append(t.pendingZipkin, span)
+        t.indexFinishedSpanLocked(span)
+}
+
+// recordIngestedZipkinSpan indexes a span that arrived via IngestZipkin.
+// Unlike recordFinishedSpan it only feeds pendingZipkin, not pendingOTLP:
+// the span belongs to whatever upstream service ingested it, and forwarding
+// it to OTLP would stamp it with this tracer's own ServiceName, silently
+// relabeling its origin.
+func (t *Tracer) recordIngestedZipkinSpan(span *Span) {
+        t.mu.Lock()
+        defer t.mu.Unlock()
+
+        t.pendingZipkin = append(t.pendingZipkin, span)
+        t.indexFinishedSpanLocked(span)
+}
+
+// indexFinishedSpanLocked updates the debug "last" / "longest" / per-trace
+// views for span. Callers must hold t.mu.
+func (t *Tracer) indexFinishedSpanLocked(span *Span) {
+        if _, ok := t.last[span.Name]; !ok {
+                t.nameOrder = append(t.nameOrder, span.Name)
+                if len(t.nameOrder) > defaultMaxTraces {
+                        oldest := t.nameOrder[0]
+                        t.nameOrder = t.nameOrder[1:]
+                        if old, ok := t.last[oldest]; ok {
+                                t.decTraceRef(old.TraceID)
+                        }
+                        if old, ok := t.longest[oldest]; ok {
+                                t.decTraceRef(old.TraceID)
+                        }
+                        delete(t.last, oldest)
+                        delete(t.longest, oldest)
+                }
+        }
+
+        if old, ok := t.last[span.Name]; ok {
+                t.decTraceRef(old.TraceID)
+        }
+        t.last[span.Name] = span
+        t.incTraceRef(span.TraceID)
+
+        if span.EndTime != nil {
+                duration := span.EndTime.Sub(span.StartTime)
+                existing, ok := t.longest[span.Name]
+                if !ok || existing.EndTime == nil || duration > existing.EndTime.Sub(existing.StartTime) {
+                        if ok {
+                                t.decTraceRef(existing.TraceID)
+                        }
+                        t.longest[span.Name] = span
+                        t.incTraceRef(span.TraceID)
+                }
+        }
+
+        if _, ok := t.traces[span.TraceID]; !ok {
+                t.traceOrder = append(t.traceOrder, span.TraceID)
+        }
+        t.traces[span.TraceID] = append(t.traces[span.TraceID], span)
+
+        t.evictOldestUnreferencedTraces()
+}
+
+// incTraceRef and decTraceRef track how many last/longest entries point
+// into a trace, so evictOldestUnreferencedTraces never drops one out
+// from under handleDebugTrace.
+func (t *Tracer) incTraceRef(traceID string) {
+        t.traceRefs[traceID]++
+}
+
+func (t *Tracer) decTraceRef(traceID string) {
+        t.traceRefs[traceID]--
+        if t.traceRefs[traceID] <= 0 {
+                delete(t.traceRefs, traceID)
+        }
+}
+
+// evictOldestUnreferencedTraces drops the oldest traces in traceOrder
+// once defaultMaxTraces is exceeded, stopping at the first trace still
+// referenced by last or longest. A trace pinned this way keeps traces
+// briefly over its cap rather than leaving a dangling TraceID behind for
+// handleDebugTrace to look up; it clears once that name's last/longest
+// moves on.
+func (t *Tracer) evictOldestUnreferencedTraces() {
+        for len(t.traceOrder) > defaultMaxTraces {
+                oldest := t.traceOrder[0]
+                if t.traceRefs[oldest] > 0 {
+                        return
+                }
+                t.traceOrder = t.traceOrder[1:]
+                delete(t.traces, oldest)
+        }
+}
+
+// traceparentVersion is the only W3C Trace Context version this tracer
+// knows how to emit and parse.
+const traceparentVersion = "00"
+
+// Inject writes the current span of ctx onto h as a W3C Trace Context
+// "traceparent" header (plus "tracestate" if one was carried on ctx),
+// so it can be forwarded across an outgoing HTTP request.
+func (t *Tracer) Inject(ctx context.Context, h http.Header) {
+        span, ok := ctx.Value("span").(*Span)
+        if !ok {
+                return
+        }
+
+        h.Set("traceparent", fmt.Sprintf("%s-%s-%s-01", traceparentVersion, span.TraceID, span.SpanID))
+        if state, ok := ctx.Value("tracestate").(string); ok && state != "" {
+                h.Set("tracestate", state)
+        }
+}
+
+// Extract parses a W3C Trace Context "traceparent" header (and
+// "tracestate" if present) from h and seeds ctx so that the next StartSpan
+// call continues the inbound trace instead of starting a new one.
+func (t *Tracer) Extract(ctx context.Context, h http.Header) context.Context {
+        traceID, parentSpanID, ok := parseTraceparent(h.Get("traceparent"))
+        if !ok {
+                return ctx
+        }
+
+        remoteParent := &Span{TraceID: traceID, SpanID: parentSpanID}
+        newCtx := context.WithValue(ctx, "span", remoteParent)
+        newCtx = context.WithValue(newCtx, "traceID", traceID)
+
+        if state := h.Get("tracestate"); state != "" {
+                newCtx = context.WithValue(newCtx, "tracestate", state)
+        }
+
+        return newCtx
+}
+
+// parseTraceparent splits a "traceparent" header value of the form
+// "00-<trace-id>-<parent-id>-01" into its trace and parent span IDs,
+// rejecting an all-zero trace or parent ID as invalid per the W3C Trace
+// Context spec rather than letting the tracer continue with them.
+func parseTraceparent(header string) (traceID, spanID string, ok bool) {
+        parts := strings.Split(header, "-")
+        if len(parts) != 4 {
+                return "", "", false
+        }
+        if parts[0] != traceparentVersion {
+                return "", "", false
+        }
+        if len(parts[1]) != 32 || len(parts[2]) != 16 {
+                return "", "", false
+        }
+        if isAllZeroHex(parts[1]) || isAllZeroHex(parts[2]) {
+                return "", "", false
+        }
+        return parts[1], parts[2], true
+}
+
+// isAllZeroHex reports whether a hex-encoded ID is all zeros, the W3C
+// Trace Context spec's definition of an invalid trace or span ID.
+func isAllZeroHex(id string) bool {
+        for _, c := range id {
+                if c != '0' {
+                        return false
+                }
+        }
+        return true
+}
+
+// otlpExportRequest is a minimal OTLP/HTTP ExportTraceServiceRequest,
+// encoded as JSON rather than protobuf to avoid pulling in the OTLP
+// collector proto bindings for what is otherwise a dependency-free tracer.
+type otlpExportRequest struct {
+        ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+        Resource   otlpResource     `json:"resource"`
+        ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+        Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+        Spans []otlpSpan `json:"spans"`
+}
+
+type otlpKeyValue struct {
+        Key   string       `json:"key"`
+        Value otlpAnyValue `json:"value"`
+}
+
+// otlpAnyValue is OTLP's tagged-union attribute value. Exactly one field
+// is populated, matching the OTLP common.v1.AnyValue JSON mapping.
+type otlpAnyValue struct {
+        StringValue *string         `json:"stringValue,omitempty"`
+        IntValue    *string         `json:"intValue,omitempty"`
+        DoubleValue *float64        `json:"doubleValue,omitempty"`
+        BoolValue   *bool           `json:"boolValue,omitempty"`
+        ArrayValue  *otlpArrayValue `json:"arrayValue,omitempty"`
+}
+
+type otlpArrayValue struct {
+        Values []otlpAnyValue `json:"values"`
+}
+
+// toOTLPAnyValue converts a Span.Attributes value (string, int64, float64,
+// bool or []string) into its OTLP AnyValue encoding, falling back to a
+// string representation for any other type.
+func toOTLPAnyValue(v any) otlpAnyValue {
+        switch val := v.(type) {
+        case string:
+                return otlpAnyValue{StringValue: &val}
+        case int64:
+                s := strconv.FormatInt(val, 10)
+                return otlpAnyValue{IntValue: &s}
+        case float64:
+                return otlpAnyValue{DoubleValue: &val}
+        case bool:
+                return otlpAnyValue{BoolValue: &val}
+        case []string:
+                values := make([]otlpAnyValue, 0, len(val))
+                for _, s := range val {
+                        s := s
+                        values = append(values, otlpAnyValue{StringValue: &s})
+                }
+                return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+        default:
+                s := fmt.Sprintf("%v", val)
+                return otlpAnyValue{StringValue: &s}
+        }
+}
+
+func toOTLPKeyValues(attrs map[string]any) []otlpKeyValue {
+        kvs := make([]otlpKeyValue, 0, len(attrs))
+        for k, v := range attrs {
+                kvs = append(kvs, otlpKeyValue{Key: k, Value: toOTLPAnyValue(v)})
+        }
+        return kvs
+}
+
+type otlpSpanEvent struct {
+        TimeUnixNano string         `json:"timeUnixNano"`
+        Name         string         `json:"name"`
+        Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpSpanLink struct {
+        TraceID    string         `json:"traceId"`
+        SpanID     string         `json:"spanId"`
+        Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpSpan struct {
+        TraceID           string          `json:"traceId"`
+        SpanID            string          `json:"spanId"`
+        ParentSpanID      string          `json:"parentSpanId,omitempty"`
+        Name              string          `json:"name"`
+        StartTimeUnixNano string          `json:"startTimeUnixNano"`
+        EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+        Attributes        []otlpKeyValue  `json:"attributes,omitempty"`
+        Events            []otlpSpanEvent `json:"events,omitempty"`
+        Links             []otlpSpanLink  `json:"links,omitempty"`
+}
+
+// ExportOTLP batches every span finished since the last call and POSTs
+// them to endpoint as an OTLP/HTTP ExportTraceServiceRequest, so spans
+// produced here can flow into Tempo, Jaeger, Honeycomb or any other
+// OTLP-speaking backend without a bridge.
+func (t *Tracer) ExportOTLP(ctx context.Context, endpoint string) error {
+        t.mu.Lock()
+        pending := t.pendingOTLP
+        t.pendingOTLP = nil
+        t.mu.Unlock()
+
+        if len(pending) == 0 {
+                return nil
+        }
+
+        spans := make([]otlpSpan, 0, len(pending))
+        for _, span := range pending {
+                if span.EndTime == nil {
+                        continue
+                }
+
+                events := make([]otlpSpanEvent, 0, len(span.Events))
+                for _, ev := range span.Events {
+                        events = append(events, otlpSpanEvent{
+                                TimeUnixNano: fmt.Sprintf("%d", ev.Time.UnixNano()),
+                                Name:         ev.Name,
+                                Attributes:   toOTLPKeyValues(ev.Attributes),
+                        })
+                }
+
+                links := make([]otlpSpanLink, 0, len(span.Links))
+                for _, link := range span.Links {
+                        links = append(links, otlpSpanLink{
+                                TraceID:    link.TraceID,
+                                SpanID:     link.SpanID,
+                                Attributes: toOTLPKeyValues(link.Attributes),
+                        })
+                }
+
+                spans = append(spans, otlpSpan{
+                        TraceID:           span.TraceID,
+                        SpanID:            span.SpanID,
+                        ParentSpanID:      span.ParentSpanID,
+                        Name:              span.Name,
+                        StartTimeUnixNano: fmt.Sprintf("%d", span.StartTime.UnixNano()),
+                        EndTimeUnixNano:   fmt.Sprintf("%d", span.EndTime.UnixNano()),
+                        Attributes:        toOTLPKeyValues(span.Attributes),
+                        Events:            events,
+                        Links:             links,
+                })
+        }
+
+        serviceName := t.ServiceName
+        req := otlpExportRequest{
+                ResourceSpans: []otlpResourceSpans{
+                        {
+                                Resource: otlpResource{
+                                        Attributes: []otlpKeyValue{
+                                                {Key: "service.name", Value: otlpAnyValue{StringValue: &serviceName}},
+                                        },
+                                },
+                                ScopeSpans: []otlpScopeSpans{{Spans: spans}},
+                        },
+                },
+        }
+
+        body, err := json.Marshal(req)
+        if err != nil {
+                t.requeuePendingOTLP(pending)
+                return fmt.Errorf("marshaling OTLP export request: %w", err)
+        }
+
+        httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+        if err != nil {
+                t.requeuePendingOTLP(pending)
+                return fmt.Errorf("building OTLP export request: %w", err)
+        }
+        httpReq.Header.Set("Content-Type", "application/json")
+
+        resp, err := http.DefaultClient.Do(httpReq)
+        if err != nil {
+                t.requeuePendingOTLP(pending)
+                return fmt.Errorf("sending OTLP export request: %w", err)
+        }
+        defer resp.Body.Close()
+
+        if resp.StatusCode >= 300 {
+                t.requeuePendingOTLP(pending)
+                return fmt.Errorf("OTLP endpoint returned status %d", resp.StatusCode)
+        }
+
+        return nil
+}
+
+// requeuePendingOTLP puts spans back at the front of pendingOTLP after a
+// failed export, ahead of anything EndSpan appended while the export was
+// in flight, so a retry sends them in the order they finished.
+func (t *Tracer) requeuePendingOTLP(spans []*Span) {
+        t.mu.Lock()
+        t.pendingOTLP = append(spans, t.pendingOTLP...)
+        t.mu.Unlock()
+}
+
+// zipkinSpan is a Zipkin v2 span as documented at
+// https://zipkin.io/zipkin-api/#/default/post_spans.
+type zipkinSpan struct {
+        TraceID       string             `json:"traceId"`
+        ID            string             `json:"id"`
+        ParentID      string             `json:"parentId,omitempty"`
+        Name          string             `json:"name"`
+        Kind          string             `json:"kind,omitempty"`
+        Timestamp     int64              `json:"timestamp"`
+        Duration      int64              `json:"duration"`
+        LocalEndpoint zipkinEndpoint     `json:"localEndpoint"`
+        Tags          map[string]string  `json:"tags,omitempty"`
+        Annotations   []zipkinAnnotation `json:"annotations,omitempty"`
+}
+
+type zipkinEndpoint struct {
+        ServiceName string `json:"serviceName"`
+}
+
+// zipkinAnnotation carries a Span.Event as a Zipkin v2 timestamped
+// annotation.
+type zipkinAnnotation struct {
+        Timestamp int64  `json:"timestamp"`
+        Value     string `json:"value"`
+}
+
+// attrsToZipkinTags stringifies Span.Attributes for Zipkin, whose tags are
+// string-only.
+func attrsToZipkinTags(attrs map[string]any) map[string]string {
+        if len(attrs) == 0 {
+                return nil
+        }
+        tags := make(map[string]string, len(attrs))
+        for k, v := range attrs {
+                if s, ok := v.(string); ok {
+                        tags[k] = s
+                        continue
+                }
+                tags[k] = fmt.Sprintf("%v", v)
+        }
+        return tags
+}
+
+// ContentTypeZipkinJSON and ContentTypeZipkinThrift select the wire format
+// IngestZipkin should expect, mirroring the Content-Type values a Zipkin
+// collector accepts on its /api/v2/spans and /api/v1/spans endpoints.
+// ContentTypeZipkinThrift is decoded by decodeZipkinThriftSpans, a minimal
+// Zipkin v1 Thrift reader covering the common fields — see its doc comment
+// for what it does and doesn't interpret.
+const (
+        ContentTypeZipkinJSON   = "application/json"
+        ContentTypeZipkinThrift = "application/x-thrift"
+)
+
+// ExportZipkin serialises every span finished since the last call as a
+// Zipkin v2 JSON array, so this tracer can sit behind (or alongside) a
+// Zipkin-speaking collector without rewriting instrumentation.
+func (t *Tracer) ExportZipkin(w io.Writer) error {
+        t.mu.Lock()
+        pending := t.pendingZipkin
+        t.pendingZipkin = nil
+        t.mu.Unlock()
+
+        if len(pending) == 0 {
+                return json.NewEncoder(w).Encode([]zipkinSpan{})
+        }
+
+        spans := make([]zipkinSpan, 0, len(pending))
+        for _, span := range pending {
+                if span.EndTime == nil {
+                        continue
+                }
+
+                var annotations []zipkinAnnotation
+                for _, ev := range span.Events {
+                        annotations = append(annotations, zipkinAnnotation{
+                                Timestamp: ev.Time.UnixMicro(),
+                                Value:     ev.Name,
+                        })
+                }
+
+                spans = append(spans, zipkinSpan{
+                        TraceID:       span.TraceID,
+                        ID:            span.SpanID,
+                        ParentID:      span.ParentSpanID,
+                        Name:          span.Name,
+                        Kind:          span.Kind,
+                        Timestamp:     span.StartTime.UnixMicro(),
+                        Duration:      span.EndTime.Sub(span.StartTime).Microseconds(),
+                        LocalEndpoint: zipkinEndpoint{ServiceName: t.ServiceName},
+                        Tags:          attrsToZipkinTags(span.Attributes),
+                        Annotations:   annotations,
+                })
+        }
+
+        if err := json.NewEncoder(w).Encode(spans); err != nil {
+                t.requeuePendingZipkin(pending)
+                return fmt.Errorf("encoding zipkin spans: %w", err)
+        }
+
+        return nil
+}
+
+// requeuePendingZipkin puts spans back at the front of pendingZipkin
+// after a failed export, ahead of anything EndSpan/IngestZipkin appended
+// while the export was in flight, so a retry still sends them.
+func (t *Tracer) requeuePendingZipkin(spans []*Span) {
+        t.mu.Lock()
+        t.pendingZipkin = append(spans, t.pendingZipkin...)
+        t.mu.Unlock()
+}
+
+// IngestZipkin reads spans encoded in contentType (either
+// ContentTypeZipkinJSON or ContentTypeZipkinThrift) from r and inserts
+// them into the tracer, normalising every trace/span/parent ID to
+// lowercase hex so they interoperate with IDs generated by StartSpan.
+func (t *Tracer) IngestZipkin(r io.Reader, contentType string) error {
+        switch contentType {
+        case ContentTypeZipkinJSON, "":
+                var spans []zipkinSpan
+                if err := json.NewDecoder(r).Decode(&spans); err != nil {
+                        return fmt.Errorf("decoding zipkin JSON: %w", err)
+                }
+                for _, zs := range spans {
+                        t.ingestZipkinSpan(zs)
+                }
+                return nil
+        case ContentTypeZipkinThrift:
+                spans, err := decodeZipkinThriftSpans(r)
+                if err != nil {
+                        return fmt.Errorf("decoding zipkin thrift: %w", err)
+                }
+                for _, zs := range spans {
+                        t.ingestZipkinSpan(zs)
+                }
+                return nil
+        default:
+                return fmt.Errorf("tracer: unsupported zipkin content type %q", contentType)
+        }
+}
+
+func (t *Tracer) ingestZipkinSpan(zs zipkinSpan) {
+        startTime := time.UnixMicro(zs.Timestamp)
+        endTime := startTime.Add(time.Duration(zs.Duration) * time.Microsecond)
+
+        attributes := make(map[string]any, len(zs.Tags))
+        for k, v := range zs.Tags {
+                attributes[k] = v
+        }
+
+        var events []SpanEvent
+        for _, ann := range zs.Annotations {
+                events = append(events, SpanEvent{Name: ann.Value, Time: time.UnixMicro(ann.Timestamp)})
+        }
+
+        span := &Span{
+                TraceID:      normalizeZipkinID(zs.TraceID),
+                SpanID:       normalizeZipkinID(zs.ID),
+                ParentSpanID: normalizeZipkinID(zs.ParentID),
+                Name:         zs.Name,
+                Kind:         zs.Kind,
+                StartTime:    startTime,
+                EndTime:      &endTime,
+                Attributes:   attributes,
+                Status:       "UNSET",
+                Events:       events,
+        }
+
+        t.recordIngestedZipkinSpan(span)
+}
+
+// normalizeZipkinID lowercases a Zipkin trace/span/parent ID so IDs ingested
+// from an upstream collector compare equal to IDs generated by StartSpan.
+func normalizeZipkinID(id string) string {
+        return strings.ToLower(id)
+}
@@ -0,0 +1,342 @@
+package tracer
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dash-xd/tracer/internal/axiomlogger"
+)
+
+// newTestTracer returns a Tracer whose Logger points at a local server that
+// always 200s, so EndSpan's Axiom logging call never fails the test for
+// reasons unrelated to what it's checking.
+func newTestTracer(t *testing.T) *Tracer {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(ts.Close)
+	return NewTracer("svc", axiomlogger.New(ts.URL, "test", "token"))
+}
+
+func TestInjectExtractRoundTrip(t *testing.T) {
+	tr := newTestTracer(t)
+	ctx, span := tr.StartSpan(context.Background(), "outbound")
+
+	h := http.Header{}
+	tr.Inject(ctx, h)
+
+	remoteCtx := tr.Extract(context.Background(), h)
+	_, child := tr.StartSpan(remoteCtx, "inbound")
+
+	if child.TraceID != span.TraceID {
+		t.Fatalf("got trace ID %s, want %s", child.TraceID, span.TraceID)
+	}
+	if child.ParentSpanID != span.SpanID {
+		t.Fatalf("got parent span ID %s, want %s", child.ParentSpanID, span.SpanID)
+	}
+}
+
+func TestExtractRejectsAllZeroIDs(t *testing.T) {
+	tr := newTestTracer(t)
+	h := http.Header{}
+	h.Set("traceparent", "00-00000000000000000000000000000000-0000000000000000-01")
+
+	ctx := tr.Extract(context.Background(), h)
+	if _, ok := ctx.Value("span").(*Span); ok {
+		t.Fatalf("Extract accepted an all-zero traceparent")
+	}
+}
+
+func TestExportOTLPRequeuesFailedSpansInOrder(t *testing.T) {
+	var bodies [][]byte
+	attempt := 0
+	otlp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt++
+		if attempt == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		buf := new(bytes.Buffer)
+		buf.ReadFrom(r.Body)
+		bodies = append(bodies, buf.Bytes())
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer otlp.Close()
+
+	tr := newTestTracer(t)
+	ctx, span1 := tr.StartSpan(context.Background(), "first")
+	tr.EndSpan(ctx, span1, "OK", nil)
+
+	if err := tr.ExportOTLP(context.Background(), otlp.URL); err == nil {
+		t.Fatalf("expected ExportOTLP to fail on first attempt")
+	}
+
+	_, span2 := tr.StartSpan(context.Background(), "second")
+	tr.EndSpan(context.Background(), span2, "OK", nil)
+
+	if err := tr.ExportOTLP(context.Background(), otlp.URL); err != nil {
+		t.Fatalf("ExportOTLP: %v", err)
+	}
+
+	if len(bodies) != 1 {
+		t.Fatalf("got %d successful export bodies, want 1", len(bodies))
+	}
+	var req otlpExportRequest
+	if err := json.Unmarshal(bodies[0], &req); err != nil {
+		t.Fatalf("unmarshaling export body: %v", err)
+	}
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 || spans[0].Name != "first" || spans[1].Name != "second" {
+		t.Fatalf("got spans %v, want [first second] in that order", otlpSpanNames(spans))
+	}
+}
+
+func otlpSpanNames(spans []otlpSpan) []string {
+	names := make([]string, len(spans))
+	for i, s := range spans {
+		names[i] = s.Name
+	}
+	return names
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("boom")
+}
+
+func TestExportZipkinRequeuesFailedSpansInOrder(t *testing.T) {
+	tr := newTestTracer(t)
+	ctx, span1 := tr.StartSpan(context.Background(), "first")
+	tr.EndSpan(ctx, span1, "OK", nil)
+
+	if err := tr.ExportZipkin(failingWriter{}); err == nil {
+		t.Fatalf("expected ExportZipkin to fail")
+	}
+
+	_, span2 := tr.StartSpan(context.Background(), "second")
+	tr.EndSpan(context.Background(), span2, "OK", nil)
+
+	var buf bytes.Buffer
+	if err := tr.ExportZipkin(&buf); err != nil {
+		t.Fatalf("ExportZipkin: %v", err)
+	}
+
+	var spans []zipkinSpan
+	if err := json.Unmarshal(buf.Bytes(), &spans); err != nil {
+		t.Fatalf("unmarshaling exported zipkin spans: %v", err)
+	}
+	if len(spans) != 2 || spans[0].Name != "first" || spans[1].Name != "second" {
+		t.Fatalf("got spans %v, want [first second] in that order", zipkinSpanNames(spans))
+	}
+}
+
+func zipkinSpanNames(spans []zipkinSpan) []string {
+	names := make([]string, len(spans))
+	for i, s := range spans {
+		names[i] = s.Name
+	}
+	return names
+}
+
+func TestIngestZipkinJSONRoundTripAndNoOTLPLeak(t *testing.T) {
+	tr := newTestTracer(t)
+
+	input := `[{
+		"traceId": "ABCDEF0000000000ABCDEF0000000000",
+		"id": "ABCDEF0000000000",
+		"name": "ingested",
+		"timestamp": 1000000,
+		"duration": 2000,
+		"localEndpoint": {"serviceName": "upstream"},
+		"tags": {"http.method": "GET"}
+	}]`
+
+	if err := tr.IngestZipkin(strings.NewReader(input), ContentTypeZipkinJSON); err != nil {
+		t.Fatalf("IngestZipkin: %v", err)
+	}
+
+	if len(tr.pendingOTLP) != 0 {
+		t.Fatalf("got %d pending OTLP spans after Zipkin ingest, want 0: ingested spans must not leak to OTLP", len(tr.pendingOTLP))
+	}
+
+	var buf bytes.Buffer
+	if err := tr.ExportZipkin(&buf); err != nil {
+		t.Fatalf("ExportZipkin: %v", err)
+	}
+
+	var spans []zipkinSpan
+	if err := json.Unmarshal(buf.Bytes(), &spans); err != nil {
+		t.Fatalf("unmarshaling exported zipkin spans: %v", err)
+	}
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if spans[0].TraceID != "abcdef0000000000abcdef0000000000" {
+		t.Fatalf("got trace ID %q, want lowercased", spans[0].TraceID)
+	}
+	if spans[0].Tags["http.method"] != "GET" {
+		t.Fatalf("tags not round-tripped: %v", spans[0].Tags)
+	}
+}
+
+// thriftFieldHeader, thriftI64Field, thriftStringField, thriftI32Field,
+// thriftStop and thriftListHeader hand-encode just enough Thrift Binary
+// Protocol to build a test payload for decodeZipkinThriftSpans, mirroring
+// what a real Zipkin v1 Thrift client would send.
+
+func thriftFieldHeader(buf *bytes.Buffer, ttype byte, id int16) {
+	buf.WriteByte(ttype)
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], uint16(id))
+	buf.Write(b[:])
+}
+
+func thriftI64Field(buf *bytes.Buffer, id int16, v int64) {
+	thriftFieldHeader(buf, thriftTypeI64, id)
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(v))
+	buf.Write(b[:])
+}
+
+func thriftStringField(buf *bytes.Buffer, id int16, s string) {
+	thriftFieldHeader(buf, thriftTypeString, id)
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(len(s)))
+	buf.Write(b[:])
+	buf.WriteString(s)
+}
+
+func thriftI32Field(buf *bytes.Buffer, id int16, v int32) {
+	thriftFieldHeader(buf, thriftTypeI32, id)
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(v))
+	buf.Write(b[:])
+}
+
+func thriftStop(buf *bytes.Buffer) {
+	buf.WriteByte(thriftTypeStop)
+}
+
+func thriftListHeader(buf *bytes.Buffer, elemType byte, size int32) {
+	buf.WriteByte(elemType)
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(size))
+	buf.Write(b[:])
+}
+
+func encodeTestThriftSpan() []byte {
+	var ann bytes.Buffer
+	thriftI64Field(&ann, 1, 1500000) // timestamp
+	thriftStringField(&ann, 2, "cs") // value
+	thriftStop(&ann)
+
+	var bann bytes.Buffer
+	thriftStringField(&bann, 1, "http.method")                 // key
+	thriftStringField(&bann, 2, "GET")                         // value
+	thriftI32Field(&bann, 3, zipkinThriftAnnotationTypeString) // annotation_type
+	thriftStop(&bann)
+
+	var span bytes.Buffer
+	thriftI64Field(&span, 1, 0x0000000000000042) // trace_id
+	thriftStringField(&span, 3, "thrift-span")   // name
+	thriftI64Field(&span, 4, 0x0000000000000007) // id
+	thriftI64Field(&span, 5, 0x0000000000000009) // parent_id
+	thriftFieldHeader(&span, thriftTypeList, 6)  // annotations
+	thriftListHeader(&span, thriftTypeStruct, 1)
+	span.Write(ann.Bytes())
+	thriftFieldHeader(&span, thriftTypeList, 8) // binary_annotations
+	thriftListHeader(&span, thriftTypeStruct, 1)
+	span.Write(bann.Bytes())
+	thriftI64Field(&span, 10, 1000000) // timestamp
+	thriftI64Field(&span, 11, 2000)    // duration
+	thriftStop(&span)
+
+	var payload bytes.Buffer
+	thriftListHeader(&payload, thriftTypeStruct, 1)
+	payload.Write(span.Bytes())
+	return payload.Bytes()
+}
+
+func TestIngestZipkinThriftRoundTrip(t *testing.T) {
+	tr := newTestTracer(t)
+
+	if err := tr.IngestZipkin(bytes.NewReader(encodeTestThriftSpan()), ContentTypeZipkinThrift); err != nil {
+		t.Fatalf("IngestZipkin: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tr.ExportZipkin(&buf); err != nil {
+		t.Fatalf("ExportZipkin: %v", err)
+	}
+
+	var spans []zipkinSpan
+	if err := json.Unmarshal(buf.Bytes(), &spans); err != nil {
+		t.Fatalf("unmarshaling exported zipkin spans: %v", err)
+	}
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	got := spans[0]
+	if got.Name != "thrift-span" {
+		t.Fatalf("got name %q, want %q", got.Name, "thrift-span")
+	}
+	if got.TraceID != "0000000000000042" {
+		t.Fatalf("got trace ID %q, want %q", got.TraceID, "0000000000000042")
+	}
+	if got.ID != "0000000000000007" {
+		t.Fatalf("got span ID %q, want %q", got.ID, "0000000000000007")
+	}
+	if got.ParentID != "0000000000000009" {
+		t.Fatalf("got parent ID %q, want %q", got.ParentID, "0000000000000009")
+	}
+	if got.Tags["http.method"] != "GET" {
+		t.Fatalf("got tags %v, want http.method=GET", got.Tags)
+	}
+	if len(got.Annotations) != 1 || got.Annotations[0].Value != "cs" {
+		t.Fatalf("got annotations %v, want one annotation with value %q", got.Annotations, "cs")
+	}
+}
+
+func TestIngestZipkinThriftRejectsMalformedPayload(t *testing.T) {
+	tr := newTestTracer(t)
+	if err := tr.IngestZipkin(strings.NewReader(""), ContentTypeZipkinThrift); err == nil {
+		t.Fatalf("expected IngestZipkin to reject a truncated Thrift payload")
+	}
+}
+
+func TestDebugHandlerEvictsOldestTrace(t *testing.T) {
+	tr := newTestTracer(t)
+	handler := tr.DebugHandler()
+
+	var firstTraceID string
+	for i := 0; i <= defaultMaxTraces; i++ {
+		ctx, span := tr.StartSpan(context.Background(), fmt.Sprintf("span-%d", i))
+		if i == 0 {
+			firstTraceID = span.TraceID
+		}
+		tr.EndSpan(ctx, span, "OK", nil)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/trace/"+firstTraceID, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("got status %d for evicted trace, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	lastRec := httptest.NewRecorder()
+	lastName := fmt.Sprintf("span-%d", defaultMaxTraces)
+	handler.ServeHTTP(lastRec, httptest.NewRequest(http.MethodGet, "/trace/"+lastName, nil))
+	if lastRec.Code != http.StatusOK {
+		t.Fatalf("got status %d for most recent span name, want %d", lastRec.Code, http.StatusOK)
+	}
+}
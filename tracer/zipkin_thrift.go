@@ -0,0 +1,523 @@
+package tracer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Thrift Binary Protocol type IDs, as used by Zipkin v1's Thrift span
+// encoding (https://github.com/openzipkin/zipkin/blob/master/zipkin.thrift).
+const (
+	thriftTypeStop   = 0
+	thriftTypeBool   = 2
+	thriftTypeByte   = 3
+	thriftTypeDouble = 4
+	thriftTypeI16    = 6
+	thriftTypeI32    = 8
+	thriftTypeI64    = 10
+	thriftTypeString = 11
+	thriftTypeStruct = 12
+	thriftTypeMap    = 13
+	thriftTypeSet    = 14
+	thriftTypeList   = 15
+)
+
+// zipkinThriftAnnotationTypeString is Zipkin v1's AnnotationType enum value
+// for a plain string tag, the only BinaryAnnotation encoding this decoder
+// turns into a readable Span.Tags entry. Other encodings (bool, bytes, i16,
+// i32, i64, double) are recorded as hex-encoded bytes instead of decoded,
+// so the tag survives round-tripping even though its original type is lost.
+const zipkinThriftAnnotationTypeString = 6
+
+// thriftReader reads Zipkin v1 spans encoded with Thrift's Binary Protocol,
+// covering just the field types zipkin.thrift's Span, Annotation and
+// BinaryAnnotation structs use.
+type thriftReader struct {
+	r io.Reader
+}
+
+func (tr *thriftReader) readN(n int32) ([]byte, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("tracer: negative thrift length %d", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(tr.r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (tr *thriftReader) readByte() (byte, error) {
+	b, err := tr.readN(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (tr *thriftReader) readI16() (int16, error) {
+	b, err := tr.readN(2)
+	if err != nil {
+		return 0, err
+	}
+	return int16(binary.BigEndian.Uint16(b)), nil
+}
+
+func (tr *thriftReader) readI32() (int32, error) {
+	b, err := tr.readN(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.BigEndian.Uint32(b)), nil
+}
+
+func (tr *thriftReader) readI64() (int64, error) {
+	b, err := tr.readN(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
+
+// readBinary reads a Thrift "binary"/"string" value: an i32 length prefix
+// followed by that many raw bytes.
+func (tr *thriftReader) readBinary() ([]byte, error) {
+	n, err := tr.readI32()
+	if err != nil {
+		return nil, err
+	}
+	return tr.readN(n)
+}
+
+func (tr *thriftReader) readString() (string, error) {
+	b, err := tr.readBinary()
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// readFieldHeader reads a struct field's type byte and, unless it's the
+// STOP marker ending the struct, the i16 field ID that follows it.
+func (tr *thriftReader) readFieldHeader() (ttype byte, id int16, err error) {
+	ttype, err = tr.readByte()
+	if err != nil || ttype == thriftTypeStop {
+		return
+	}
+	id, err = tr.readI16()
+	return
+}
+
+// skip discards a value of the given type, recursing into structs, lists,
+// sets and maps, so a field this decoder doesn't care about (or a future
+// field it doesn't know about) doesn't desync the rest of the struct.
+func (tr *thriftReader) skip(ttype byte) error {
+	switch ttype {
+	case thriftTypeBool, thriftTypeByte:
+		_, err := tr.readByte()
+		return err
+	case thriftTypeDouble:
+		_, err := tr.readN(8)
+		return err
+	case thriftTypeI16:
+		_, err := tr.readI16()
+		return err
+	case thriftTypeI32:
+		_, err := tr.readI32()
+		return err
+	case thriftTypeI64:
+		_, err := tr.readI64()
+		return err
+	case thriftTypeString:
+		_, err := tr.readBinary()
+		return err
+	case thriftTypeStruct:
+		for {
+			ft, _, err := tr.readFieldHeader()
+			if err != nil {
+				return err
+			}
+			if ft == thriftTypeStop {
+				return nil
+			}
+			if err := tr.skip(ft); err != nil {
+				return err
+			}
+		}
+	case thriftTypeMap:
+		keyType, err := tr.readByte()
+		if err != nil {
+			return err
+		}
+		valType, err := tr.readByte()
+		if err != nil {
+			return err
+		}
+		size, err := tr.readI32()
+		if err != nil {
+			return err
+		}
+		for i := int32(0); i < size; i++ {
+			if err := tr.skip(keyType); err != nil {
+				return err
+			}
+			if err := tr.skip(valType); err != nil {
+				return err
+			}
+		}
+		return nil
+	case thriftTypeSet, thriftTypeList:
+		elemType, err := tr.readByte()
+		if err != nil {
+			return err
+		}
+		size, err := tr.readI32()
+		if err != nil {
+			return err
+		}
+		for i := int32(0); i < size; i++ {
+			if err := tr.skip(elemType); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("tracer: unknown thrift type %d", ttype)
+	}
+}
+
+// formatThriftID hex-encodes a Thrift i64 trace/span/parent ID the same way
+// Zipkin v2 JSON does: lowercase, zero-padded to 16 hex digits.
+func formatThriftID(v int64) string {
+	return fmt.Sprintf("%016x", uint64(v))
+}
+
+// formatThriftTraceID combines a Zipkin v1 Thrift span's trace_id and
+// optional trace_id_high into the 128-bit hex trace ID Zipkin v2 JSON
+// uses, falling back to the plain 64-bit form when trace_id_high is unset,
+// matching Zipkin's own wire-format conversion.
+func formatThriftTraceID(high, low int64) string {
+	if high == 0 {
+		return formatThriftID(low)
+	}
+	return formatThriftID(high) + formatThriftID(low)
+}
+
+// decodeZipkinThriftSpans decodes a Zipkin v1 Thrift payload, a raw
+// TBinaryProtocol-encoded list of Span structs (the wire format Zipkin
+// collectors accept on /api/v1/spans), into the same zipkinSpan type
+// ExportZipkin and the JSON ingest path use.
+//
+// This is a minimal decoder covering the common fields: trace/span/parent
+// IDs, name, timestamp, duration, annotations (-> Span.Events) and
+// string-valued binary annotations (-> Span.Attributes). Other Thrift
+// fields (debug, non-string binary annotation types, endpoint hosts) are
+// read past rather than interpreted.
+func decodeZipkinThriftSpans(r io.Reader) ([]zipkinSpan, error) {
+	tr := &thriftReader{r: r}
+
+	elemType, err := tr.readByte()
+	if err != nil {
+		return nil, fmt.Errorf("reading thrift list element type: %w", err)
+	}
+	if elemType != thriftTypeStruct {
+		return nil, fmt.Errorf("tracer: thrift zipkin ingest expects a list of structs, got element type %d", elemType)
+	}
+	size, err := tr.readI32()
+	if err != nil {
+		return nil, fmt.Errorf("reading thrift list size: %w", err)
+	}
+	if size < 0 {
+		return nil, fmt.Errorf("tracer: thrift zipkin ingest got negative list size %d", size)
+	}
+
+	spans := make([]zipkinSpan, 0, size)
+	for i := int32(0); i < size; i++ {
+		span, err := tr.readZipkinSpan()
+		if err != nil {
+			return nil, fmt.Errorf("reading thrift span %d: %w", i, err)
+		}
+		spans = append(spans, span)
+	}
+	return spans, nil
+}
+
+func (tr *thriftReader) readZipkinSpan() (zipkinSpan, error) {
+	var (
+		traceID, traceIDHigh int64
+		spanID, parentID     int64
+		hasParent            bool
+		name                 string
+		timestamp, duration  int64
+		annotations          []zipkinAnnotation
+		tags                 map[string]string
+	)
+
+	for {
+		ft, id, err := tr.readFieldHeader()
+		if err != nil {
+			return zipkinSpan{}, err
+		}
+		if ft == thriftTypeStop {
+			break
+		}
+
+		switch id {
+		case 1: // trace_id
+			if ft != thriftTypeI64 {
+				if err := tr.skip(ft); err != nil {
+					return zipkinSpan{}, err
+				}
+				continue
+			}
+			traceID, err = tr.readI64()
+		case 3: // name
+			if ft != thriftTypeString {
+				if err := tr.skip(ft); err != nil {
+					return zipkinSpan{}, err
+				}
+				continue
+			}
+			name, err = tr.readString()
+		case 4: // id
+			if ft != thriftTypeI64 {
+				if err := tr.skip(ft); err != nil {
+					return zipkinSpan{}, err
+				}
+				continue
+			}
+			spanID, err = tr.readI64()
+		case 5: // parent_id
+			if ft != thriftTypeI64 {
+				if err := tr.skip(ft); err != nil {
+					return zipkinSpan{}, err
+				}
+				continue
+			}
+			parentID, err = tr.readI64()
+			hasParent = true
+		case 6: // annotations
+			if ft != thriftTypeList {
+				if err := tr.skip(ft); err != nil {
+					return zipkinSpan{}, err
+				}
+				continue
+			}
+			annotations, err = tr.readAnnotationList()
+		case 8: // binary_annotations
+			if ft != thriftTypeList {
+				if err := tr.skip(ft); err != nil {
+					return zipkinSpan{}, err
+				}
+				continue
+			}
+			tags, err = tr.readBinaryAnnotationTags()
+		case 10: // timestamp
+			if ft != thriftTypeI64 {
+				if err := tr.skip(ft); err != nil {
+					return zipkinSpan{}, err
+				}
+				continue
+			}
+			timestamp, err = tr.readI64()
+		case 11: // duration
+			if ft != thriftTypeI64 {
+				if err := tr.skip(ft); err != nil {
+					return zipkinSpan{}, err
+				}
+				continue
+			}
+			duration, err = tr.readI64()
+		case 12: // trace_id_high
+			if ft != thriftTypeI64 {
+				if err := tr.skip(ft); err != nil {
+					return zipkinSpan{}, err
+				}
+				continue
+			}
+			traceIDHigh, err = tr.readI64()
+		default:
+			err = tr.skip(ft)
+		}
+		if err != nil {
+			return zipkinSpan{}, err
+		}
+	}
+
+	zs := zipkinSpan{
+		TraceID:     formatThriftTraceID(traceIDHigh, traceID),
+		ID:          formatThriftID(spanID),
+		Name:        name,
+		Timestamp:   timestamp,
+		Duration:    duration,
+		Tags:        tags,
+		Annotations: annotations,
+	}
+	if hasParent {
+		zs.ParentID = formatThriftID(parentID)
+	}
+	return zs, nil
+}
+
+func (tr *thriftReader) readAnnotationList() ([]zipkinAnnotation, error) {
+	elemType, err := tr.readByte()
+	if err != nil {
+		return nil, err
+	}
+	size, err := tr.readI32()
+	if err != nil {
+		return nil, err
+	}
+	if elemType != thriftTypeStruct {
+		for i := int32(0); i < size; i++ {
+			if err := tr.skip(elemType); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+
+	annotations := make([]zipkinAnnotation, 0, size)
+	for i := int32(0); i < size; i++ {
+		ann, err := tr.readAnnotation()
+		if err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, ann)
+	}
+	return annotations, nil
+}
+
+func (tr *thriftReader) readAnnotation() (zipkinAnnotation, error) {
+	var a zipkinAnnotation
+	for {
+		ft, id, err := tr.readFieldHeader()
+		if err != nil {
+			return a, err
+		}
+		if ft == thriftTypeStop {
+			return a, nil
+		}
+
+		switch id {
+		case 1: // timestamp
+			if ft != thriftTypeI64 {
+				if err := tr.skip(ft); err != nil {
+					return a, err
+				}
+				continue
+			}
+			a.Timestamp, err = tr.readI64()
+		case 2: // value
+			if ft != thriftTypeString {
+				if err := tr.skip(ft); err != nil {
+					return a, err
+				}
+				continue
+			}
+			a.Value, err = tr.readString()
+		default:
+			err = tr.skip(ft)
+		}
+		if err != nil {
+			return a, err
+		}
+	}
+}
+
+func (tr *thriftReader) readBinaryAnnotationTags() (map[string]string, error) {
+	elemType, err := tr.readByte()
+	if err != nil {
+		return nil, err
+	}
+	size, err := tr.readI32()
+	if err != nil {
+		return nil, err
+	}
+	if elemType != thriftTypeStruct {
+		for i := int32(0); i < size; i++ {
+			if err := tr.skip(elemType); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+	if size == 0 {
+		return nil, nil
+	}
+
+	tags := make(map[string]string, size)
+	for i := int32(0); i < size; i++ {
+		key, value, ok, err := tr.readBinaryAnnotation()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			tags[key] = value
+		}
+	}
+	return tags, nil
+}
+
+// readBinaryAnnotation reads one Zipkin v1 BinaryAnnotation struct,
+// returning ok=false if it has no key or no value to report (e.g. a
+// struct this decoder couldn't make sense of).
+func (tr *thriftReader) readBinaryAnnotation() (key, value string, ok bool, err error) {
+	var (
+		annotationType int32
+		rawValue       []byte
+		haveValue      bool
+	)
+
+	for {
+		ft, id, ferr := tr.readFieldHeader()
+		if ferr != nil {
+			return "", "", false, ferr
+		}
+		if ft == thriftTypeStop {
+			break
+		}
+
+		switch id {
+		case 1: // key
+			if ft != thriftTypeString {
+				if e := tr.skip(ft); e != nil {
+					return "", "", false, e
+				}
+				continue
+			}
+			key, err = tr.readString()
+		case 2: // value
+			if ft != thriftTypeString {
+				if e := tr.skip(ft); e != nil {
+					return "", "", false, e
+				}
+				continue
+			}
+			rawValue, err = tr.readBinary()
+			haveValue = true
+		case 3: // annotation_type
+			if ft != thriftTypeI32 {
+				if e := tr.skip(ft); e != nil {
+					return "", "", false, e
+				}
+				continue
+			}
+			annotationType, err = tr.readI32()
+		default:
+			err = tr.skip(ft)
+		}
+		if err != nil {
+			return "", "", false, err
+		}
+	}
+
+	if key == "" || !haveValue {
+		return "", "", false, nil
+	}
+	if annotationType == zipkinThriftAnnotationTypeString {
+		return key, string(rawValue), true, nil
+	}
+	return key, fmt.Sprintf("%x", rawValue), true, nil
+}